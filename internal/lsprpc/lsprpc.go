@@ -85,7 +85,7 @@ func (s *streamServer) ServeStream(ctx context.Context, conn jsonrpc2.Conn) erro
 		protocol.Handlers(
 			handshaker(session, executable, s.daemon,
 				protocol.ServerHandler(svr,
-					jsonrpc2.MethodNotFound))))
+					gnoHandler(svr, jsonrpc2.MethodNotFound)))))
 	if s.daemon {
 		log.Printf("Session %s: connected", session.ID())
 		defer log.Printf("Session %s: exited", session.ID())
@@ -211,7 +211,7 @@ func (f *forwarder) ServeStream(ctx context.Context, clientConn jsonrpc2.Conn) e
 		protocol.Handlers(
 			f.handler(
 				protocol.ServerHandler(server,
-					jsonrpc2.MethodNotFound))))
+					gnoHandler(server, jsonrpc2.MethodNotFound)))))
 
 	select {
 	case <-serverConn.Done():
@@ -510,6 +510,15 @@ func handshaker(session *cache.Session, goplsPath string, logHandshakes bool, ha
 	}
 }
 
+// gnoHandler wraps handler to serve the custom gno/* requests, if svr
+// supports them.
+func gnoHandler(svr protocol.Server, handler jsonrpc2.Handler) jsonrpc2.Handler {
+	if gnoSvr, ok := svr.(protocol.GnoServer); ok {
+		return protocol.GnoServerHandler(gnoSvr, handler)
+	}
+	return handler
+}
+
 func sendError(ctx context.Context, reply jsonrpc2.Replier, err error) {
 	err = fmt.Errorf("%v: %w", err, jsonrpc2.ErrParse)
 	if err := reply(ctx, nil, err); err != nil {
@@ -373,3 +373,54 @@ func TestEmptySlices(t *testing.T) {
 		t.Errorf("unexpectedly got %s, want %s", buf, good)
 	}
 }
+
+// GnoModulesServer serves the gno/listModules request.
+type GnoModulesServer struct {
+	fakeServer
+	protocol.GnoServer // unimplemented methods panic
+}
+
+func (GnoModulesServer) ListModules(_ context.Context, params *protocol.GnoListModulesParams) (*protocol.GnoListModulesResult, error) {
+	return &protocol.GnoListModulesResult{
+		Modules: []protocol.GnoModule{{
+			Path:  "gno.land/r/demo/foo",
+			Name:  "foo",
+			Dir:   params.Dir,
+			Realm: true,
+		}},
+	}, nil
+}
+
+func TestGnoRequests(t *testing.T) {
+	ctx := context.Background()
+	tsDirect, tsForwarded, cleanup := setupForwarding(ctx, t, GnoModulesServer{})
+	defer cleanup()
+
+	for _, test := range []struct {
+		serverType string
+		ts         servertest.Connector
+	}{
+		{"direct", tsDirect},
+		{"forwarder", tsForwarded},
+	} {
+		t.Run(test.serverType, func(t *testing.T) {
+			cc := test.ts.Connect(ctx)
+			cc.Go(ctx, protocol.Handlers(jsonrpc2.MethodNotFound))
+
+			var got protocol.GnoListModulesResult
+			params := &protocol.GnoListModulesParams{Dir: "file:///ws"}
+			if err := protocol.Call(ctx, cc, protocol.GnoListModulesMethod, params, &got); err != nil {
+				t.Fatalf("gno/listModules: %v", err)
+			}
+			want := protocol.GnoModule{Path: "gno.land/r/demo/foo", Name: "foo", Dir: "file:///ws", Realm: true}
+			if len(got.Modules) != 1 || got.Modules[0] != want {
+				t.Errorf("gno/listModules = %+v, want [%+v]", got.Modules, want)
+			}
+
+			var res any
+			if err := protocol.Call(ctx, cc, "gno/noSuchMethod", nil, &res); err == nil || !strings.Contains(err.Error(), "method not found") {
+				t.Errorf("gno/noSuchMethod: got error %v, want method not found", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol
+
+// This file defines the custom gno/* requests served by gnopls in
+// addition to the standard LSP methods. They give editor extensions
+// (vscode-gno, the neovim plugin, ...) a stable, typed surface for
+// gno-specific features, instead of relying on ad-hoc
+// workspace/executeCommand invocations.
+//
+// See doc/features/extensions.md for the user-facing documentation.
+
+import (
+	"context"
+
+	"github.com/gnoverse/gnopls/internal/jsonrpc2"
+)
+
+// Custom request methods.
+const (
+	GnoListModulesMethod   = "gno/listModules"
+	GnoRenderPreviewMethod = "gno/renderPreview"
+	GnoRunTestMethod       = "gno/runTest"
+	GnoChainStatusMethod   = "gno/chainStatus"
+)
+
+// GnoServer is the interface of the custom gno/* requests.
+type GnoServer interface {
+	// ListModules reports the gno modules (packages and realms)
+	// known to the server's workspace.
+	ListModules(context.Context, *GnoListModulesParams) (*GnoListModulesResult, error)
+	// RenderPreview evaluates the Render function of a realm and
+	// returns its markdown output.
+	RenderPreview(context.Context, *GnoRenderPreviewParams) (*GnoRenderPreviewResult, error)
+	// RunTest runs the tests of a gno package and returns their output.
+	RunTest(context.Context, *GnoRunTestParams) (*GnoRunTestResult, error)
+	// ChainStatus reports the status of a gno.land chain node.
+	ChainStatus(context.Context, *GnoChainStatusParams) (*GnoChainStatusResult, error)
+}
+
+// GnoListModulesParams are the parameters of the gno/listModules request.
+type GnoListModulesParams struct {
+	// Dir, if set, restricts the result to modules enclosed by this
+	// directory.
+	Dir DocumentURI `json:"dir,omitempty"`
+}
+
+// GnoListModulesResult is the result of the gno/listModules request.
+type GnoListModulesResult struct {
+	Modules []GnoModule `json:"modules"`
+}
+
+// GnoModule describes a gno module of the workspace.
+type GnoModule struct {
+	// Path is the module path declared by the gno.mod file,
+	// e.g. "gno.land/r/demo/boards".
+	Path string `json:"path"`
+	// Name is the package name.
+	Name string `json:"name"`
+	// Dir is the directory containing the module sources.
+	Dir DocumentURI `json:"dir"`
+	// GnoMod is the URI of the file declaring the module, either
+	// gnomod.toml or gno.mod, if any.
+	GnoMod DocumentURI `json:"gnomod,omitempty"`
+	// Realm reports whether the module is a realm (gno.land/r/...)
+	// rather than a pure package (gno.land/p/...).
+	Realm bool `json:"realm"`
+}
+
+// GnoRenderPreviewParams are the parameters of the gno/renderPreview request.
+type GnoRenderPreviewParams struct {
+	// URI is a file or directory of the realm to render.
+	URI DocumentURI `json:"uri"`
+	// Path is the argument passed to Render, i.e. the part of the
+	// gnoweb URL following the colon.
+	Path string `json:"path"`
+}
+
+// GnoRenderPreviewResult is the result of the gno/renderPreview request.
+type GnoRenderPreviewResult struct {
+	// Markdown is the output of the Render function.
+	Markdown string `json:"markdown"`
+}
+
+// GnoRunTestParams are the parameters of the gno/runTest request.
+type GnoRunTestParams struct {
+	// URI is a file or directory of the package to test.
+	URI DocumentURI `json:"uri"`
+	// Run, if set, is a regular expression selecting the tests to
+	// run, as for the -run flag of `gno test`.
+	Run string `json:"run,omitempty"`
+	// Verbose requests verbose test output.
+	Verbose bool `json:"verbose,omitempty"`
+}
+
+// GnoRunTestResult is the result of the gno/runTest request.
+type GnoRunTestResult struct {
+	// Passed reports whether all selected tests passed.
+	Passed bool `json:"passed"`
+	// Output is the combined output of the test run.
+	Output string `json:"output"`
+}
+
+// GnoChainStatusParams are the parameters of the gno/chainStatus request.
+type GnoChainStatusParams struct {
+	// Remote is the RPC address of the node to query. If empty, it
+	// defaults to 127.0.0.1:26657, the address of a local node.
+	Remote string `json:"remote,omitempty"`
+}
+
+// GnoChainStatusResult is the result of the gno/chainStatus request.
+type GnoChainStatusResult struct {
+	Remote            string `json:"remote"`
+	ChainID           string `json:"chainID"`
+	LatestBlockHeight int64  `json:"latestBlockHeight"`
+	LatestBlockTime   string `json:"latestBlockTime"`
+	CatchingUp        bool   `json:"catchingUp"`
+}
+
+// GnoServerHandler returns a handler that dispatches the custom gno/*
+// requests to server, delegating all other requests to handler.
+func GnoServerHandler(server GnoServer, handler jsonrpc2.Handler) jsonrpc2.Handler {
+	return func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+		handled, err := gnoServerDispatch(ctx, server, reply, req)
+		if handled || err != nil {
+			return err
+		}
+		return handler(ctx, reply, req)
+	}
+}
+
+func gnoServerDispatch(ctx context.Context, server GnoServer, reply jsonrpc2.Replier, r jsonrpc2.Request) (bool, error) {
+	defer recoverHandlerPanic(r.Method())
+	switch r.Method() {
+	case GnoListModulesMethod:
+		var params GnoListModulesParams
+		if err := UnmarshalJSON(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.ListModules(ctx, &params)
+		if err != nil {
+			return true, reply(ctx, nil, err)
+		}
+		return true, reply(ctx, resp, nil)
+
+	case GnoRenderPreviewMethod:
+		var params GnoRenderPreviewParams
+		if err := UnmarshalJSON(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.RenderPreview(ctx, &params)
+		if err != nil {
+			return true, reply(ctx, nil, err)
+		}
+		return true, reply(ctx, resp, nil)
+
+	case GnoRunTestMethod:
+		var params GnoRunTestParams
+		if err := UnmarshalJSON(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.RunTest(ctx, &params)
+		if err != nil {
+			return true, reply(ctx, nil, err)
+		}
+		return true, reply(ctx, resp, nil)
+
+	case GnoChainStatusMethod:
+		var params GnoChainStatusParams
+		if err := UnmarshalJSON(r.Params(), &params); err != nil {
+			return true, sendParseError(ctx, reply, err)
+		}
+		resp, err := server.ChainStatus(ctx, &params)
+		if err != nil {
+			return true, reply(ctx, nil, err)
+		}
+		return true, reply(ctx, resp, nil)
+
+	default:
+		return false, nil
+	}
+}
+
+// The serverDispatcher forwards the gno/* requests too, so that they
+// work through a forwarding gnopls (-remote).
+
+func (s *serverDispatcher) ListModules(ctx context.Context, params *GnoListModulesParams) (*GnoListModulesResult, error) {
+	var result *GnoListModulesResult
+	if err := s.sender.Call(ctx, GnoListModulesMethod, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+func (s *serverDispatcher) RenderPreview(ctx context.Context, params *GnoRenderPreviewParams) (*GnoRenderPreviewResult, error) {
+	var result *GnoRenderPreviewResult
+	if err := s.sender.Call(ctx, GnoRenderPreviewMethod, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+func (s *serverDispatcher) RunTest(ctx context.Context, params *GnoRunTestParams) (*GnoRunTestResult, error) {
+	var result *GnoRunTestResult
+	if err := s.sender.Call(ctx, GnoRunTestMethod, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+func (s *serverDispatcher) ChainStatus(ctx context.Context, params *GnoChainStatusParams) (*GnoChainStatusResult, error) {
+	var result *GnoChainStatusResult
+	if err := s.sender.Call(ctx, GnoChainStatusMethod, params, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
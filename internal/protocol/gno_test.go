@@ -0,0 +1,81 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package protocol_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/gnoverse/gnopls/internal/jsonrpc2"
+	"github.com/gnoverse/gnopls/internal/protocol"
+)
+
+// gnoServer records the gno/* requests it receives.
+type gnoServer struct {
+	protocol.GnoServer // unimplemented methods panic
+	runTest            *protocol.GnoRunTestParams
+}
+
+func (s *gnoServer) RunTest(_ context.Context, params *protocol.GnoRunTestParams) (*protocol.GnoRunTestResult, error) {
+	s.runTest = params
+	return &protocol.GnoRunTestResult{Passed: true, Output: "ok"}, nil
+}
+
+func TestGnoServerHandler(t *testing.T) {
+	ctx := context.Background()
+	call := func(t *testing.T, s *gnoServer, method string, params json.RawMessage) (result any, err error, fellThrough bool) {
+		t.Helper()
+		req, callErr := jsonrpc2.NewCall(jsonrpc2.NewIntID(1), method, params)
+		if callErr != nil {
+			t.Fatal(callErr)
+		}
+		next := func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+			fellThrough = true
+			return jsonrpc2.MethodNotFound(ctx, reply, req)
+		}
+		reply := func(_ context.Context, res any, replyErr error) error {
+			result, err = res, replyErr
+			return nil
+		}
+		if handlerErr := protocol.GnoServerHandler(s, next)(ctx, reply, req); handlerErr != nil {
+			t.Fatal(handlerErr)
+		}
+		return result, err, fellThrough
+	}
+
+	t.Run("dispatch", func(t *testing.T) {
+		s := new(gnoServer)
+		result, err, fellThrough := call(t, s, protocol.GnoRunTestMethod, json.RawMessage(`{"uri":"file:///a/b.gno","run":"TestX"}`))
+		if err != nil || fellThrough {
+			t.Fatalf("gno/runTest: err=%v, fell through=%v", err, fellThrough)
+		}
+		if s.runTest == nil || s.runTest.URI != "file:///a/b.gno" || s.runTest.Run != "TestX" {
+			t.Errorf("RunTest received %+v", s.runTest)
+		}
+		if res, ok := result.(*protocol.GnoRunTestResult); !ok || !res.Passed || res.Output != "ok" {
+			t.Errorf("gno/runTest result = %#v", result)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		s := new(gnoServer)
+		_, err, fellThrough := call(t, s, protocol.GnoRunTestMethod, json.RawMessage(`{"uri":42}`))
+		if !errors.Is(err, jsonrpc2.ErrParse) || fellThrough {
+			t.Errorf("malformed gno/runTest: err=%v, fell through=%v, want parse error", err, fellThrough)
+		}
+		if s.runTest != nil {
+			t.Errorf("RunTest called despite parse error")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err, fellThrough := call(t, new(gnoServer), "gno/noSuchMethod", nil)
+		if !errors.Is(err, jsonrpc2.ErrMethodNotFound) || !fellThrough {
+			t.Errorf("gno/noSuchMethod: err=%v, fell through=%v, want method not found", err, fellThrough)
+		}
+	})
+}
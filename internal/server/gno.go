@@ -0,0 +1,229 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+// This file implements the custom gno/* requests; see
+// [protocol.GnoServer].
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gnoverse/gnopls/internal/cache/metadata"
+	"github.com/gnoverse/gnopls/internal/event"
+	"github.com/gnoverse/gnopls/internal/protocol"
+)
+
+// defaultChainRemote is the RPC address of a local gno.land node, as
+// started by gnodev or gnoland.
+const defaultChainRemote = "127.0.0.1:26657"
+
+func (s *server) ListModules(ctx context.Context, params *protocol.GnoListModulesParams) (*protocol.GnoListModulesResult, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.listModules")
+	defer done()
+
+	result := &protocol.GnoListModulesResult{Modules: []protocol.GnoModule{}}
+	seen := make(map[protocol.DocumentURI]bool)
+	for _, view := range s.session.Views() {
+		snapshot, release, err := view.Snapshot()
+		if err != nil {
+			continue // view was shut down
+		}
+		defer release()
+
+		metas, err := snapshot.WorkspaceMetadata(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, mod := range gnoModulesOf(metas, params.Dir, seen) {
+			for _, name := range gnoModFiles {
+				uri := protocol.URIFromPath(filepath.Join(mod.Dir.Path(), name))
+				fh, err := snapshot.ReadFile(ctx, uri)
+				if err != nil {
+					return nil, err
+				}
+				if _, err := fh.Content(); err == nil {
+					mod.GnoMod = uri
+					break
+				}
+			}
+			result.Modules = append(result.Modules, mod)
+		}
+	}
+	return result, nil
+}
+
+// gnoModFiles are the names of the files that may declare a gno
+// module, in order of preference.
+var gnoModFiles = []string{"gnomod.toml", "gno.mod"}
+
+// gnoModulesOf returns the modules of the given workspace packages that
+// are enclosed by dir (if set), skipping test variants, which share
+// their directory with the package under test, and directories already
+// in seen. It records the directories of the returned modules in seen.
+func gnoModulesOf(metas []*metadata.Package, dir protocol.DocumentURI, seen map[protocol.DocumentURI]bool) []protocol.GnoModule {
+	var mods []protocol.GnoModule
+	for _, mp := range metas {
+		if mp.ForTest != "" || strings.HasSuffix(string(mp.Name), "_test") || len(mp.CompiledGoFiles) == 0 {
+			continue
+		}
+		pkgDir := mp.CompiledGoFiles[0].Dir()
+		if seen[pkgDir] || dir != "" && !dir.Encloses(pkgDir) {
+			continue
+		}
+		seen[pkgDir] = true
+		mods = append(mods, protocol.GnoModule{
+			Path:  string(mp.PkgPath),
+			Name:  string(mp.Name),
+			Dir:   pkgDir,
+			Realm: isRealmPath(string(mp.PkgPath)),
+		})
+	}
+	return mods
+}
+
+func (s *server) RenderPreview(ctx context.Context, params *protocol.GnoRenderPreviewParams) (*protocol.GnoRenderPreviewResult, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.renderPreview")
+	defer done()
+
+	dir := packageDir(params.URI)
+	expr := fmt.Sprintf("println(Render(%s))", strconv.Quote(params.Path))
+	var stdout, stderr bytes.Buffer
+	cmd, err := s.gnoCommand(ctx, dir, "run", "-expr", expr, ".")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("rendering %s: %v\n%s%s", dir, err, &stdout, &stderr)
+	}
+	return &protocol.GnoRenderPreviewResult{
+		// println terminates the output with a newline.
+		Markdown: strings.TrimSuffix(stdout.String(), "\n"),
+	}, nil
+}
+
+func (s *server) RunTest(ctx context.Context, params *protocol.GnoRunTestParams) (*protocol.GnoRunTestResult, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.runTest")
+	defer done()
+
+	args := []string{"test"}
+	if params.Verbose {
+		args = append(args, "-v")
+	}
+	if params.Run != "" {
+		args = append(args, "-run", params.Run)
+	}
+	args = append(args, ".")
+
+	var out bytes.Buffer
+	cmd, err := s.gnoCommand(ctx, packageDir(params.URI), args...)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err = cmd.Run()
+	if _, ok := err.(*exec.ExitError); err != nil && !ok {
+		// The tool could not be run at all, as opposed to failing tests.
+		return nil, fmt.Errorf("running gno test: %v", err)
+	}
+	return &protocol.GnoRunTestResult{
+		Passed: err == nil,
+		Output: out.String(),
+	}, nil
+}
+
+func (s *server) ChainStatus(ctx context.Context, params *protocol.GnoChainStatusParams) (*protocol.GnoChainStatusResult, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.chainStatus")
+	defer done()
+
+	remote := params.Remote
+	if remote == "" {
+		remote = defaultChainRemote
+	}
+	url := remote
+	if !strings.Contains(url, "://") {
+		url = "http://" + url
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(url, "/")+"/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("querying %s: %v", remote, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("querying %s: %s", remote, resp.Status)
+	}
+
+	// The subset of the tm2 /status response that we care about.
+	var status struct {
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+			SyncInfo struct {
+				LatestBlockHeight string `json:"latest_block_height"`
+				LatestBlockTime   string `json:"latest_block_time"`
+				CatchingUp        bool   `json:"catching_up"`
+			} `json:"sync_info"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decoding status of %s: %v", remote, err)
+	}
+	height, err := strconv.ParseInt(status.Result.SyncInfo.LatestBlockHeight, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("decoding status of %s: invalid block height: %v", remote, err)
+	}
+	return &protocol.GnoChainStatusResult{
+		Remote:            remote,
+		ChainID:           status.Result.NodeInfo.Network,
+		LatestBlockHeight: height,
+		LatestBlockTime:   status.Result.SyncInfo.LatestBlockTime,
+		CatchingUp:        status.Result.SyncInfo.CatchingUp,
+	}, nil
+}
+
+// gnoCommand returns a command that runs the gno tool with the given
+// arguments in dir, using the environment configured for the server.
+// It returns an error if the gno tool cannot be found.
+func (s *server) gnoCommand(ctx context.Context, dir string, args ...string) (*exec.Cmd, error) {
+	gno, err := exec.LookPath("gno")
+	if err != nil {
+		return nil, fmt.Errorf("cannot find the gno tool (is it installed and in $PATH?): %v", err)
+	}
+	cmd := exec.CommandContext(ctx, gno, args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Environ(), s.Options().EnvSlice()...)
+	return cmd, nil
+}
+
+// packageDir returns the directory of the package denoted by uri,
+// which may be either a .gno file or a directory.
+func packageDir(uri protocol.DocumentURI) string {
+	if filepath.Ext(uri.Path()) == ".gno" {
+		return uri.Dir().Path()
+	}
+	return uri.Path()
+}
+
+// isRealmPath reports whether pkgPath is the path of a realm, that is,
+// a package whose state is persisted on chain (gno.land/r/...).
+// Only the gno.land domain hosts realms.
+func isRealmPath(pkgPath string) bool {
+	return strings.HasPrefix(pkgPath, "gno.land/r/")
+}
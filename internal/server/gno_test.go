@@ -0,0 +1,107 @@
+// Copyright 2024 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gnoverse/gnopls/internal/cache/metadata"
+	"github.com/gnoverse/gnopls/internal/protocol"
+)
+
+func TestIsRealmPath(t *testing.T) {
+	for _, test := range []struct {
+		path string
+		want bool
+	}{
+		{"gno.land/r/demo/boards", true},
+		{"gno.land/p/demo/avl", false},
+		{"gno.land/r", false},
+		{"strings", false},
+		{"example.com/r/foo", false},
+	} {
+		if got := isRealmPath(test.path); got != test.want {
+			t.Errorf("isRealmPath(%q) = %v, want %v", test.path, got, test.want)
+		}
+	}
+}
+
+func TestChainStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":"","result":{
+			"node_info":{"network":"dev"},
+			"sync_info":{"latest_block_height":"42","latest_block_time":"2024-12-17T13:32:27Z","catching_up":false}}}`)
+	}))
+	defer ts.Close()
+
+	s := &server{}
+	got, err := s.ChainStatus(context.Background(), &protocol.GnoChainStatusParams{Remote: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := protocol.GnoChainStatusResult{
+		Remote:            ts.URL,
+		ChainID:           "dev",
+		LatestBlockHeight: 42,
+		LatestBlockTime:   "2024-12-17T13:32:27Z",
+	}
+	if *got != want {
+		t.Errorf("ChainStatus() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestGnoModulesOf(t *testing.T) {
+	pkg := func(pkgPath, name, forTest, file string) *metadata.Package {
+		return &metadata.Package{
+			PkgPath:         metadata.PackagePath(pkgPath),
+			Name:            metadata.PackageName(name),
+			ForTest:         metadata.PackagePath(forTest),
+			CompiledGoFiles: []protocol.DocumentURI{protocol.URIFromPath(file)},
+		}
+	}
+	metas := []*metadata.Package{
+		pkg("gno.land/r/demo/foo", "foo", "", "/ws/r/foo/foo.gno"),
+		pkg("gno.land/r/demo/foo", "foo", "gno.land/r/demo/foo", "/ws/r/foo/foo.gno"),
+		pkg("gno.land/r/demo/foo_test", "foo_test", "", "/ws/r/foo/foo_test.gno"),
+		pkg("gno.land/p/demo/bar", "bar", "", "/ws/p/bar/bar.gno"),
+		{PkgPath: "gno.land/p/demo/empty", Name: "empty"},
+	}
+
+	for _, test := range []struct {
+		dir  string
+		want []string
+	}{
+		{"", []string{"gno.land/r/demo/foo", "gno.land/p/demo/bar"}},
+		{"/ws/p", []string{"gno.land/p/demo/bar"}},
+		{"/elsewhere", nil},
+	} {
+		var dir protocol.DocumentURI
+		if test.dir != "" {
+			dir = protocol.URIFromPath(test.dir)
+		}
+		var got []string
+		for _, mod := range gnoModulesOf(metas, dir, make(map[protocol.DocumentURI]bool)) {
+			got = append(got, mod.Path)
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("gnoModulesOf(dir=%q) = %v, want %v", test.dir, got, test.want)
+		}
+	}
+
+	// Directories already seen (e.g. in another view) are skipped.
+	seen := map[protocol.DocumentURI]bool{protocol.URIFromPath("/ws/r/foo"): true}
+	if mods := gnoModulesOf(metas, "", seen); len(mods) != 1 || mods[0].Path != "gno.land/p/demo/bar" || mods[0].Realm {
+		t.Errorf("gnoModulesOf with seen foo = %+v, want only bar", mods)
+	}
+}